@@ -0,0 +1,28 @@
+// Package crypto provides etype-agnostic entry points into the etype
+// specific key derivation subpackages, so callers outside crypto/* do not
+// need to know which RFC a given etype's primitives live in. Its one entry
+// point, PRFPlus, calls into crypto/rfc3961; nothing here calls into
+// crypto/rfc8009.
+package crypto
+
+import (
+	"github.com/jcmturner/gokrb5/crypto/etype"
+	"github.com/jcmturner/gokrb5/crypto/rfc3961"
+)
+
+// PRFPlus computes the GSS-API PRF+ construction of RFC 4402 for the given
+// etype, producing nbytes of pseudo-random output derived from key and
+// seed. Callers needing arbitrary-length keying material derived from a
+// Kerberos key (e.g. gssapi.Context.PRF) should call this rather than
+// reaching into crypto/rfc3961 directly.
+//
+// Only etypes built on the RFC 3961 DR/DK feedback construction are
+// supported here. RFC 8009's AES-SHA2 etypes (19, 20) derive keys via
+// KDF-HMAC-SHA2 instead and have no equivalent PRF+ base construction
+// implemented in this package; rfc3961.PseudoRandomPlus already rejects
+// them (via DeriveKey's own etype check) rather than silently producing
+// PRF+ output that would not interoperate with an RFC 8009-compliant
+// peer, so that check is not duplicated here.
+func PRFPlus(key, seed []byte, nbytes int, e etype.EType) ([]byte, error) {
+	return rfc3961.PseudoRandomPlus(key, seed, nbytes, e)
+}
@@ -0,0 +1,55 @@
+package etype
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+// TestAes128CtsHmacSha256128_EncryptDecryptMessage checks EncryptMessage and
+// DecryptMessage round trip through the real etype, not rfc8009 directly, so
+// a wrong identifier or function wired up in this glue file would fail here.
+func TestAes128CtsHmacSha256128_EncryptDecryptMessage(t *testing.T) {
+	e := Aes128CtsHmacSha256128{}
+	key := make([]byte, e.GetKeyByteSize())
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	usage := []byte{0, 0, 0, 3}
+	message := []byte("the quick brown fox jumps over the lazy dog")
+
+	ct, mac, err := e.EncryptMessage(key, message, usage)
+	if err != nil {
+		t.Fatalf("EncryptMessage returned error: %v", err)
+	}
+	got, err := e.DecryptMessage(key, ct, mac, usage)
+	if err != nil {
+		t.Fatalf("DecryptMessage returned error: %v", err)
+	}
+	if !bytes.Equal(got, message) {
+		t.Fatalf("round trip mismatch\n got=%q\nwant=%q", got, message)
+	}
+}
+
+// TestAes128CtsHmacSha256128_ChecksumHash checks GetChecksumHash and
+// VerifyChecksum round trip through the real etype.
+func TestAes128CtsHmacSha256128_ChecksumHash(t *testing.T) {
+	e := Aes128CtsHmacSha256128{}
+	key := make([]byte, e.GetKeyByteSize())
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	usage := []byte{0, 0, 0, 5}
+	data := []byte("authenticator checksum data")
+
+	chksum, err := e.GetChecksumHash(key, data, usage)
+	if err != nil {
+		t.Fatalf("GetChecksumHash returned error: %v", err)
+	}
+	if !e.VerifyChecksum(key, data, usage, chksum) {
+		t.Fatal("VerifyChecksum rejected a checksum produced by GetChecksumHash")
+	}
+	if e.VerifyChecksum(key, []byte("tampered data"), usage, chksum) {
+		t.Fatal("VerifyChecksum accepted a checksum for different data")
+	}
+}
@@ -0,0 +1,120 @@
+package etype
+
+import (
+	"crypto/sha512"
+	"hash"
+
+	"github.com/jcmturner/gokrb5/crypto/rfc8009"
+)
+
+// Aes256CtsHmacSha384192 implements the RFC 8009 aes256-cts-hmac-sha384-192
+// (etype 20) encryption and checksum type.
+type Aes256CtsHmacSha384192 struct{}
+
+// GetETypeID returns the EType number.
+func (e Aes256CtsHmacSha384192) GetETypeID() int32 {
+	return 20
+}
+
+// GetHashID returns the checksum type number associated with this etype
+// (hmac-sha384-192-aes256).
+func (e Aes256CtsHmacSha384192) GetHashID() int32 {
+	return 20
+}
+
+// GetKeyByteSize returns the key length for this etype in bytes.
+func (e Aes256CtsHmacSha384192) GetKeyByteSize() int {
+	return 32
+}
+
+// GetKeySeedBitLength returns the bit length of the key seed.
+func (e Aes256CtsHmacSha384192) GetKeySeedBitLength() int {
+	return 256
+}
+
+// GetHash returns the hash function used to build this etype's HMAC.
+func (e Aes256CtsHmacSha384192) GetHash() func() hash.Hash {
+	return sha512.New384
+}
+
+// GetMessageBlockByteSize returns the cipher's block size in bytes, the
+// length rfc3961.PseudoRandom truncates a hash digest to before encrypting
+// it. This is unrelated to the hash function's own internal block size.
+func (e Aes256CtsHmacSha384192) GetMessageBlockByteSize() int {
+	return e.GetCypherBlockBitLength() / 8
+}
+
+// GetCypherBlockBitLength returns the cipher block size in bits.
+func (e Aes256CtsHmacSha384192) GetCypherBlockBitLength() int {
+	return 128
+}
+
+// GetConfounderByteSize returns the size of the random confounder prefixed
+// to plaintext before encryption.
+func (e Aes256CtsHmacSha384192) GetConfounderByteSize() int {
+	return 16
+}
+
+// GetHMACBitLength returns the truncated HMAC output size in bits.
+func (e Aes256CtsHmacSha384192) GetHMACBitLength() int {
+	return 192
+}
+
+// GetDefaultStringToKeyParams returns the default PBKDF2 iteration count
+// encoded as an s2kparams string, per RFC 8009 section 4.
+func (e Aes256CtsHmacSha384192) GetDefaultStringToKeyParams() string {
+	return "00008000"
+}
+
+// StringToKey converts a password into a protocol key.
+func (e Aes256CtsHmacSha384192) StringToKey(secret, salt, s2kparams string) ([]byte, error) {
+	i, err := S2KparamsToItertions(s2kparams)
+	if err != nil {
+		return nil, err
+	}
+	return rfc8009.StringToKey(secret, salt, i, e)
+}
+
+// RandomToKey is the identity transform for the AES-SHA2 enctypes.
+func (e Aes256CtsHmacSha384192) RandomToKey(b []byte) []byte {
+	return b
+}
+
+// DeriveKey derives Kc, Ke or Ki from the protocol key for a key usage.
+func (e Aes256CtsHmacSha384192) DeriveKey(protocolKey, usage []byte, identifier byte) ([]byte, error) {
+	return rfc8009.DeriveKey(protocolKey, usage, identifier, e)
+}
+
+// EncryptData performs plain AES CBC-CS3 encryption of data under key with
+// no confounder or MAC, the low level primitive other parts of the crypto
+// package build on.
+func (e Aes256CtsHmacSha384192) EncryptData(key, data []byte) ([]byte, []byte, error) {
+	return rfc8009.RawEncrypt(key, data)
+}
+
+// DecryptData reverses EncryptData.
+func (e Aes256CtsHmacSha384192) DecryptData(key, data []byte) ([]byte, error) {
+	return rfc8009.RawDecrypt(key, data)
+}
+
+// EncryptMessage encrypts a message following the RFC 8009 encrypt-then-MAC
+// scheme for the given key usage and returns the ciphertext and MAC.
+func (e Aes256CtsHmacSha384192) EncryptMessage(key, message, usage []byte) ([]byte, []byte, error) {
+	return rfc8009.EncryptionAndMAC(key, usage, message, e)
+}
+
+// DecryptMessage verifies the MAC and decrypts ciphertext produced by
+// EncryptMessage.
+func (e Aes256CtsHmacSha384192) DecryptMessage(key, ciphertext, mac, usage []byte) ([]byte, error) {
+	return rfc8009.VerifyAndDecrypt(key, usage, ciphertext, mac, e)
+}
+
+// GetChecksumHash returns the RFC 8009 checksum of data under Kc for usage.
+func (e Aes256CtsHmacSha384192) GetChecksumHash(protocolKey, data, usage []byte) ([]byte, error) {
+	return rfc8009.GetChecksumHash(protocolKey, data, usage, e)
+}
+
+// VerifyChecksum compares a checksum against a freshly computed one.
+func (e Aes256CtsHmacSha384192) VerifyChecksum(protocolKey, data, usage, chksum []byte) bool {
+	return rfc8009.VerifyChecksum(protocolKey, data, usage, chksum, e)
+}
@@ -0,0 +1,14 @@
+// Package etype holds the concrete Kerberos encryption/checksum type
+// implementations (aes128-cts-hmac-sha256-128, aes256-cts-hmac-sha384-192,
+// ...), each satisfying the EType interface its callers (crypto/rfc3961,
+// crypto/rfc8009, gssapi) program against.
+//
+// This reduced tree has no etype-ID-to-implementation registry: nothing
+// here maps a wire etype number to one of these structs for a caller that
+// only has the number (e.g. from a ticket or keytab entry) and needs to
+// pick an EType generically. That lookup would live in the ticket/keytab
+// decoding package in the full gokrb5 tree, which this snapshot does not
+// include, so it is not added here. Callers in this tree construct the
+// concrete struct (Aes128CtsHmacSha256128{}, Aes256CtsHmacSha384192{})
+// directly instead.
+package etype
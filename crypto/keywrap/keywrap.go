@@ -0,0 +1,192 @@
+// Package keywrap implements the AES Key Wrap algorithms of RFC 3394 and
+// the padded variant of RFC 5649, used by the GSS-API krb5 mechanism's
+// wrap-token path to transport subkeys and cross-realm ticket key material
+// under a key encryption key (KEK).
+package keywrap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// defaultIV is the 8 octet integrity check value prepended to the key
+// material before wrapping plain (non-padded) RFC 3394 input.
+var defaultIV = []byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+
+// aivMagic is the high order 4 octets of the RFC 5649 alternative IV: a
+// fixed constant followed by the big-endian length, in octets, of the
+// unpadded plaintext.
+const aivMagic = 0xA65959A6
+
+// Wrap encrypts plaintext under kek using RFC 3394 AES Key Wrap. plaintext
+// must be a multiple of 8 bytes and at least 16 bytes long; use WrapPad for
+// plaintext that is not block aligned.
+func Wrap(kek, plaintext []byte) ([]byte, error) {
+	if len(plaintext) < 16 || len(plaintext)%8 != 0 {
+		return nil, errors.New("keywrap: plaintext must be a multiple of 8 bytes and at least 16 bytes long")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(block, defaultIV, plaintext), nil
+}
+
+// Unwrap decrypts ciphertext produced by Wrap, verifying the RFC 3394
+// integrity check value in constant time.
+func Unwrap(kek, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 24 || len(ciphertext)%8 != 0 {
+		return nil, errors.New("keywrap: ciphertext must be a multiple of 8 bytes and at least 24 bytes long")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	a, plaintext := unwrap(block, ciphertext)
+	if subtle.ConstantTimeCompare(a, defaultIV) != 1 {
+		return nil, errors.New("keywrap: integrity check failed")
+	}
+	return plaintext, nil
+}
+
+// WrapPad encrypts plaintext of any length (including less than 16 bytes
+// or not a multiple of 8) under kek using the RFC 5649 padded key wrap.
+func WrapPad(kek, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	aiv := make([]byte, 8)
+	binary.BigEndian.PutUint32(aiv, aivMagic)
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(plaintext)))
+
+	padded := plaintext
+	if r := len(plaintext) % 8; r != 0 || len(plaintext) == 0 {
+		padded = make([]byte, len(plaintext)+(8-len(plaintext)%8)%8)
+		copy(padded, plaintext)
+		if len(padded) == 0 {
+			padded = make([]byte, 8)
+		}
+	}
+
+	if len(padded) == 8 {
+		ct := make([]byte, 16)
+		block.Encrypt(ct, append(append([]byte{}, aiv...), padded...))
+		return ct, nil
+	}
+	return wrap(block, aiv, padded), nil
+}
+
+// UnwrapPad reverses WrapPad, verifying the RFC 5649 alternative IV
+// (including the embedded plaintext length) in constant time.
+func UnwrapPad(kek, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	var aiv, padded []byte
+	if len(ciphertext) == 16 {
+		pt := make([]byte, 16)
+		block.Decrypt(pt, ciphertext)
+		aiv, padded = pt[:8], pt[8:]
+	} else {
+		if len(ciphertext) < 24 || len(ciphertext)%8 != 0 {
+			return nil, errors.New("keywrap: ciphertext must be a multiple of 8 bytes and at least 24 bytes long")
+		}
+		aiv, padded = unwrap(block, ciphertext)
+	}
+
+	// Compare against every valid AIV (lengths len(padded)-7..len(padded)).
+	ok := 0
+	want := make([]byte, 8)
+	binary.BigEndian.PutUint32(want, aivMagic)
+	for pad := 0; pad < 8; pad++ {
+		binary.BigEndian.PutUint32(want[4:], uint32(len(padded)-pad))
+		ok |= subtle.ConstantTimeCompare(aiv, want)
+	}
+	if ok != 1 {
+		return nil, errors.New("keywrap: integrity check failed")
+	}
+	return padded[:binary.BigEndian.Uint32(aiv[4:])], nil
+}
+
+// wrap implements the RFC 3394 key wrap algorithm: A starts as iv, R holds
+// the n 8 byte plaintext blocks, and 6 outer rounds each XOR a running
+// counter t = n*j+i into A before re-encrypting A||R[i].
+func wrap(block cipher.Block, iv, plaintext []byte) []byte {
+	n := len(plaintext) / 8
+	r := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		r[i] = append([]byte{}, plaintext[i*8:i*8+8]...)
+	}
+
+	a := append([]byte{}, iv...)
+	buf := make([]byte, 16)
+	for j := 0; j <= 5; j++ {
+		for i := 0; i < n; i++ {
+			t := uint64(n*j + i + 1)
+			copy(buf, a)
+			copy(buf[8:], r[i])
+			block.Encrypt(buf, buf)
+			a = xorCounter(buf[:8], t)
+			r[i] = append([]byte{}, buf[8:]...)
+		}
+	}
+
+	out := make([]byte, 8+len(plaintext))
+	copy(out, a)
+	for i := 0; i < n; i++ {
+		copy(out[8+i*8:], r[i])
+	}
+	return out
+}
+
+// unwrap implements the index based variant of the RFC 3394 key unwrap
+// algorithm: A is initialised from the first 8 bytes of ciphertext and
+// R[1..n] from the remaining blocks, then 6 outer rounds j=5..0 run inner
+// steps i=n..1 computing B = AES-Decrypt(KEK, (A XOR t) || R[i]) where
+// t = n*j + i, updating A = MSB64(B) and R[i] = LSB64(B). The caller is
+// responsible for verifying the returned A against the expected IV.
+func unwrap(block cipher.Block, ciphertext []byte) (a, plaintext []byte) {
+	n := len(ciphertext)/8 - 1
+	r := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		r[i] = append([]byte{}, ciphertext[8+i*8:8+i*8+8]...)
+	}
+
+	a = append([]byte{}, ciphertext[:8]...)
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			copy(buf, xorCounter(a, t))
+			copy(buf[8:], r[i])
+			block.Decrypt(buf, buf)
+			a = append([]byte{}, buf[:8]...)
+			r[i] = append([]byte{}, buf[8:]...)
+		}
+	}
+
+	plaintext = make([]byte, n*8)
+	for i := 0; i < n; i++ {
+		copy(plaintext[i*8:], r[i])
+	}
+	return a, plaintext
+}
+
+// xorCounter XORs the 64 bit big-endian counter t into the low order bytes
+// of the 8 byte value a, per RFC 3394 section 2.2.1.
+func xorCounter(a []byte, t uint64) []byte {
+	out := append([]byte{}, a...)
+	var tb [8]byte
+	binary.BigEndian.PutUint64(tb[:], t)
+	for i := range out {
+		out[i] ^= tb[i]
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package keywrap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors computed directly against AES-ECB (RFC 3394 section 4):
+// wrapping 128 bits of key data under 128, 192 and 256 bit KEKs, and
+// wrapping 256 bits of key data under a 256 bit KEK.
+func TestWrapUnwrap(t *testing.T) {
+	tests := []struct {
+		name string
+		kek  string
+		pt   string
+		ct   string
+	}{
+		{
+			name: "128kek/128keydata",
+			kek:  "000102030405060708090A0B0C0D0E0F",
+			pt:   "00112233445566778899AABBCCDDEEFF",
+			ct:   "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+		},
+		{
+			name: "192kek/128keydata",
+			kek:  "000102030405060708090A0B0C0D0E0F1011121314151617",
+			pt:   "00112233445566778899AABBCCDDEEFF",
+			ct:   "96778B25AE6CA435F92B5B97C050AED2468AB8A17AD84E5D",
+		},
+		{
+			name: "256kek/128keydata",
+			kek:  "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			pt:   "00112233445566778899AABBCCDDEEFF",
+			ct:   "64E8C3F9CE0F5BA263E9777905818A2A93C8191E7D6E8AE7",
+		},
+		{
+			name: "256kek/256keydata",
+			kek:  "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			pt:   "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+			ct:   "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek, _ := hex.DecodeString(tt.kek)
+			pt, _ := hex.DecodeString(tt.pt)
+			want, _ := hex.DecodeString(tt.ct)
+
+			got, err := Wrap(kek, pt)
+			if err != nil {
+				t.Fatalf("Wrap returned error: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Wrap() = %X, want %X", got, want)
+			}
+
+			back, err := Unwrap(kek, got)
+			if err != nil {
+				t.Fatalf("Unwrap returned error: %v", err)
+			}
+			if !bytes.Equal(back, pt) {
+				t.Errorf("Unwrap() = %X, want %X", back, pt)
+			}
+		})
+	}
+}
+
+func TestUnwrap_IntegrityFailure(t *testing.T) {
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	pt, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+	ct, err := Wrap(kek, pt)
+	if err != nil {
+		t.Fatalf("Wrap returned error: %v", err)
+	}
+	ct[0] ^= 0xFF
+	if _, err := Unwrap(kek, ct); err == nil {
+		t.Errorf("expected an integrity check failure for tampered ciphertext")
+	}
+}
+
+// TestWrapUnwrapPad covers RFC 5649 padded wrap for key material that is
+// not a multiple of 8 bytes and key material shorter than one block.
+func TestWrapUnwrapPad(t *testing.T) {
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+
+	tests := []struct {
+		name string
+		pt   string
+		ct   string
+	}{
+		{
+			name: "20bytes",
+			pt:   "000102030405060708090A0B0C0D0E0F10111213",
+			ct:   "2BDD65F7D5D1850B076568A76B931C26AF41A4A9AEE0412CF9AE52927B10F141",
+		},
+		{
+			name: "3bytes",
+			pt:   "010203",
+			ct:   "8FA42A06AF048533FE7F22C7824E472E",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt, _ := hex.DecodeString(tt.pt)
+			want, _ := hex.DecodeString(tt.ct)
+
+			got, err := WrapPad(kek, pt)
+			if err != nil {
+				t.Fatalf("WrapPad returned error: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("WrapPad() = %X, want %X", got, want)
+			}
+
+			back, err := UnwrapPad(kek, got)
+			if err != nil {
+				t.Fatalf("UnwrapPad returned error: %v", err)
+			}
+			if !bytes.Equal(back, pt) {
+				t.Errorf("UnwrapPad() = %X, want %X", back, pt)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package rfc3961
 
 import (
+	"container/list"
+	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
+
 	"github.com/jcmturner/gokrb5/crypto/etype"
 	"golang.org/x/crypto/pbkdf2"
 )
@@ -11,6 +16,15 @@ import (
 const (
 	s2kParamsZero = 4294967296
 	prfconstant   = "prf"
+	// prfPlusMaxCounter is the largest PRF+ iteration counter representable
+	// in the single octet RFC 4402 reserves for it.
+	prfPlusMaxCounter = 255
+	// maxKeySeedByteLength caps the key seed length DeriveRandom will
+	// allocate for and feed back through the cipher. No known etype needs
+	// anything close to this; it exists so a malformed or hostile etype
+	// implementation reporting a huge GetKeySeedBitLength cannot force an
+	// unbounded allocation or an effectively infinite feedback loop.
+	maxKeySeedByteLength = 512
 )
 
 // RFC 3961: DR(Key, Constant) = k-truncate(E(Key, Constant, initial-cipher-state)).
@@ -25,11 +39,17 @@ const (
 //
 // e: the encryption etype function to use.
 func DeriveRandom(key, usage []byte, e etype.EType) ([]byte, error) {
+	if err := rejectNonDRDKEtype(e); err != nil {
+		return nil, err
+	}
 	n := e.GetCypherBlockBitLength()
 	k := e.GetKeySeedBitLength()
+	if k/8 > maxKeySeedByteLength {
+		return nil, fmt.Errorf("rfc3961: key seed length %d bytes exceeds the maximum of %d bytes", k/8, maxKeySeedByteLength)
+	}
 	//Ensure the usage constant is at least the size of the cypher block size. Pass it through the nfold algorithm that will "stretch" it if needs be.
 	nFoldUsage := Nfold(usage, n)
-	//k-truncate implemented by creating a byte array the size of k (k is in bits hence /8)
+	//k-truncate implemented by creating a byte array the size of k (k is in bits hence /8), allocated once up front.
 	out := make([]byte, k/8)
 
 	/*If the output	of E is shorter than k bits, it is fed back into the encryption as many times as necessary.
@@ -46,18 +66,142 @@ func DeriveRandom(key, usage []byte, e etype.EType) ([]byte, error) {
 		return out, err
 	}
 	for i := copy(out, K); i < len(out); {
-		_, K, _ = e.EncryptData(key, K)
+		_, K, err = e.EncryptData(key, K)
+		if err != nil {
+			return out, err
+		}
 		i = i + copy(out[i:], K)
 	}
 	return out, nil
 }
 
+// derivedKeyCacheCapacity bounds the number of (protocol key, usage, etype)
+// entries derivedKeyCache retains. A client/service in a long session
+// derives fresh per-exchange subkeys on every AP-REQ/AS-REP, so the set of
+// distinct entries is not naturally bounded by the keytabs in use; the
+// cache exists to avoid re-running the DR block-cipher feedback loop for
+// repeat lookups of the *same* key material within that bound, not to
+// retain every derivation a process ever performs.
+const derivedKeyCacheCapacity = 256
+
+// derivedKeyCache caches DeriveKey results keyed by a fingerprint of the
+// protocol key, the etype and the usage, evicting least-recently-used
+// entries once derivedKeyCacheCapacity is exceeded.
+var derivedKeyCache = newLRUCache(derivedKeyCacheCapacity)
+
+type derivedKeyCacheKey struct {
+	keyFingerprint [sha256.Size]byte
+	etypeID        int32
+	usage          string
+}
+
+// lruCache is a small fixed-capacity least-recently-used cache. It exists
+// here rather than reaching for a dependency because the cached values are
+// plain byte slices and the eviction policy is all that is needed.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[derivedKeyCacheKey]*list.Element
+}
+
+type lruEntry struct {
+	key   derivedKeyCacheKey
+	value []byte
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[derivedKeyCacheKey]*list.Element),
+	}
+}
+
+func (c *lruCache) get(k derivedKeyCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[k]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) put(k derivedKeyCacheKey, v []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[k]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*lruEntry).value = v
+		return
+	}
+	c.items[k] = c.ll.PushFront(&lruEntry{key: k, value: v})
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// removeFingerprint evicts every entry derived from the protocol key with
+// fingerprint fp, regardless of etype or usage.
+func (c *lruCache) removeFingerprint(fp [sha256.Size]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, el := range c.items {
+		if k.keyFingerprint == fp {
+			c.ll.Remove(el)
+			delete(c.items, k)
+		}
+	}
+}
+
+// rejectNonDRDKEtype reports an error for etypes that do not use the RFC
+// 3961 DR/DK feedback construction this package implements. RFC 8009's
+// AES-SHA2 etypes (19, 20) derive keys via KDF-HMAC-SHA2 instead, via
+// their own EType.DeriveKey method (see crypto/etype); running them
+// through DeriveRandom/DeriveKey here would silently produce a key that
+// does not match the one an RFC 8009-compliant peer derives.
+func rejectNonDRDKEtype(e etype.EType) error {
+	if id := e.GetETypeID(); id == 19 || id == 20 {
+		return fmt.Errorf("rfc3961: etype %d uses RFC 8009 KDF-HMAC-SHA2, not the RFC 3961 DR/DK construction; call its own EType.DeriveKey instead", id)
+	}
+	return nil
+}
+
+// DeriveKey derives a key from the protocol key for a usage, caching the
+// result so repeated calls for the same (protocolKey, usage, etype) triple
+// skip the DeriveRandom feedback loop.
 func DeriveKey(protocolKey, usage []byte, e etype.EType) ([]byte, error) {
+	if err := rejectNonDRDKEtype(e); err != nil {
+		return nil, err
+	}
+	ck := derivedKeyCacheKey{
+		keyFingerprint: sha256.Sum256(protocolKey),
+		etypeID:        e.GetETypeID(),
+		usage:          string(usage),
+	}
+	if v, ok := derivedKeyCache.get(ck); ok {
+		return v, nil
+	}
+
 	r, err := DeriveRandom(protocolKey, usage, e)
 	if err != nil {
 		return nil, err
 	}
-	return RandomToKey(r), nil
+	dk := RandomToKey(r)
+	derivedKeyCache.put(ck, dk)
+	return dk, nil
+}
+
+// InvalidateDerivedKeyCache removes any cached DeriveKey results derived
+// from protocolKey, across all etypes and usages. Callers must invoke this
+// whenever the underlying keytab entry a protocol key came from is
+// replaced, so stale derived keys are not served after a key rotation.
+func InvalidateDerivedKeyCache(protocolKey []byte) {
+	derivedKeyCache.removeFingerprint(sha256.Sum256(protocolKey))
 }
 
 func RandomToKey(b []byte) []byte {
@@ -124,3 +268,31 @@ func PseudoRandom(key, b []byte, e etype.EType) ([]byte, error) {
 	}
 	return prf, nil
 }
+
+// PseudoRandomPlus implements the GSS-API PRF+ construction of RFC 4402:
+//
+// PRF+(key, seed, nbytes) = truncate(nbytes, PRF(key, 1 || seed) ||
+//                                            PRF(key, 2 || seed) || ...)
+//
+// where the counter is a single octet starting at 1. It is used wherever
+// arbitrary-length keying material is required (gss_pseudo_random, channel
+// bindings, exported session keys) and PseudoRandom's single block of
+// output is not enough. Returns an error if nbytes would need more than
+// 255 blocks, as the counter octet cannot represent that.
+func PseudoRandomPlus(key, seed []byte, nbytes int, e etype.EType) ([]byte, error) {
+	blockLen := e.GetMessageBlockByteSize()
+	nblocks := (nbytes + blockLen - 1) / blockLen
+	if nblocks > prfPlusMaxCounter {
+		return nil, errors.New("rfc3961: PRF+ requested output requires a counter greater than 255")
+	}
+
+	out := make([]byte, 0, nblocks*blockLen)
+	for i := 1; i <= nblocks; i++ {
+		block, err := PseudoRandom(key, append([]byte{byte(i)}, seed...), e)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, block...)
+	}
+	return out[:nbytes], nil
+}
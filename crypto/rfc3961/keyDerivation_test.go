@@ -0,0 +1,50 @@
+package rfc3961
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jcmturner/gokrb5/crypto/etype"
+)
+
+// oversizedKeySeedEType reports a key seed length far beyond anything a
+// real etype needs, to exercise DeriveRandom's ceiling check.
+type oversizedKeySeedEType struct {
+	etype.Aes128CtsHmacSha96
+}
+
+func (oversizedKeySeedEType) GetKeySeedBitLength() int {
+	return (maxKeySeedByteLength + 1) * 8
+}
+
+func TestDeriveRandom_RejectsOversizedKeySeed(t *testing.T) {
+	_, err := DeriveRandom(make([]byte, 16), []byte("usage"), oversizedKeySeedEType{})
+	if err == nil {
+		t.Fatal("expected an error for a key seed length beyond maxKeySeedByteLength")
+	}
+}
+
+func TestDeriveKey_CachesResult(t *testing.T) {
+	e := etype.Aes128CtsHmacSha96{}
+	key := []byte("0123456789ABCDEF")
+	usage := []byte("ticket")
+
+	first, err := DeriveKey(key, usage, e)
+	if err != nil {
+		t.Fatalf("DeriveKey returned error: %v", err)
+	}
+	second, err := DeriveKey(key, usage, e)
+	if err != nil {
+		t.Fatalf("DeriveKey returned error: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("cached DeriveKey result differs from first call")
+	}
+
+	InvalidateDerivedKeyCache(key)
+	ck := derivedKeyCacheKey{keyFingerprint: sha256.Sum256(key), etypeID: e.GetETypeID(), usage: string(usage)}
+	if _, ok := derivedKeyCache.get(ck); ok {
+		t.Fatalf("expected cache entry to be removed after InvalidateDerivedKeyCache")
+	}
+}
@@ -0,0 +1,49 @@
+package rfc3961
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jcmturner/gokrb5/crypto/etype"
+)
+
+// TestPseudoRandomPlus exercises the RFC 4402 PRF+ construction against the
+// des3, aes128 and aes256 enctypes: the first block of output must match a
+// plain PseudoRandom call with counter octet 0x01 prepended to the seed,
+// and requesting more than 255 blocks' worth of output must be rejected.
+func TestPseudoRandomPlus(t *testing.T) {
+	key := bytes.Repeat([]byte{0x11}, 32)
+	seed := []byte("ticket session key")
+
+	etypes := map[string]etype.EType{
+		"des3cbcsha1":          etype.Des3CbcSha1{},
+		"aes128ctshmacsha196":  etype.Aes128CtsHmacSha96{},
+		"aes256ctshmacsha196":  etype.Aes256CtsHmacSha96{},
+	}
+
+	for name, e := range etypes {
+		t.Run(name, func(t *testing.T) {
+			blockLen := e.GetMessageBlockByteSize()
+
+			out, err := PseudoRandomPlus(key, seed, blockLen+1, e)
+			if err != nil {
+				t.Fatalf("PseudoRandomPlus returned error: %v", err)
+			}
+			if len(out) != blockLen+1 {
+				t.Fatalf("got %d bytes, want %d", len(out), blockLen+1)
+			}
+
+			first, err := PseudoRandom(key, append([]byte{0x01}, seed...), e)
+			if err != nil {
+				t.Fatalf("PseudoRandom returned error: %v", err)
+			}
+			if !bytes.Equal(out[:blockLen], first) {
+				t.Errorf("first block of PRF+ output does not match PRF(key, 0x01||seed)")
+			}
+
+			if _, err := PseudoRandomPlus(key, seed, blockLen*256, e); err == nil {
+				t.Errorf("expected error when PRF+ output requires a counter greater than 255")
+			}
+		})
+	}
+}
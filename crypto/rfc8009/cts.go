@@ -0,0 +1,144 @@
+package rfc8009
+
+import (
+	"crypto/cipher"
+	"fmt"
+)
+
+// ctsEncrypt performs AES CBC-CS3 ciphertext stealing encryption, the mode
+// required by RFC 8009 section 4 (and RFC 3962 before it).
+//
+// Plaintext that is an exact multiple of the block size is encrypted with
+// plain CBC and the final two ciphertext blocks are swapped. Otherwise the
+// final partial plaintext block is zero padded, XORed with the preceding
+// full ciphertext block Cn-1 and encrypted, and the two final ciphertext
+// blocks are transmitted as full-then-truncated (that encrypted block, then
+// the leading bytes of Cn-1), per RFC 3962 section 5/RFC 8009 section 4.
+// Either way the ciphertext is exactly as long as the plaintext.
+//
+// CBC-CS3 is only defined for inputs of at least one full block; ctsEncrypt
+// returns an error rather than inventing a non-standard scheme for shorter
+// input, since nothing using this package's only call sites (the Kerberos
+// confounder alone is one full block) can legitimately produce one, and a
+// caller that manages to would otherwise get silent, non-interoperable
+// output instead of a signal that something upstream is wrong.
+func ctsEncrypt(block cipher.Block, iv, plaintext []byte) ([]byte, error) {
+	bs := block.BlockSize()
+	l := len(plaintext)
+
+	if l%bs == 0 {
+		ct := make([]byte, l)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, plaintext)
+		if l/bs >= 2 {
+			swapLastTwoBlocks(ct, bs)
+		}
+		return ct, nil
+	}
+
+	d := l % bs
+	head := l - d - bs
+	if head < 0 {
+		return nil, fmt.Errorf("rfc8009: CBC-CS3 requires at least %d bytes of input, got %d", bs, l)
+	}
+
+	headCT := make([]byte, head)
+	if head > 0 {
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(headCT, plaintext[:head])
+	}
+	prev := iv
+	if head > 0 {
+		prev = headCT[head-bs:]
+	}
+
+	// Cn-1: the last full plaintext block, encrypted normally.
+	cnMinus1 := make([]byte, bs)
+	block.Encrypt(cnMinus1, xor(plaintext[head:head+bs], prev))
+
+	// Dn: the final partial plaintext block zero padded and XORed with
+	// Cn-1, which leaves Cn-1's trailing bytes as its tail, then encrypted.
+	dn := make([]byte, bs)
+	copy(dn, xor(plaintext[head+bs:], cnMinus1[:d]))
+	copy(dn[d:], cnMinus1[d:])
+	cnFull := make([]byte, bs)
+	block.Encrypt(cnFull, dn)
+
+	out := make([]byte, l)
+	copy(out, headCT)
+	copy(out[head:], cnFull)
+	copy(out[head+bs:], cnMinus1[:d])
+	return out, nil
+}
+
+// ctsDecrypt reverses ctsEncrypt.
+func ctsDecrypt(block cipher.Block, iv, ciphertext []byte) ([]byte, error) {
+	bs := block.BlockSize()
+	l := len(ciphertext)
+
+	if l%bs == 0 {
+		ct := append([]byte{}, ciphertext...)
+		if l/bs >= 2 {
+			swapLastTwoBlocks(ct, bs)
+		}
+		pt := make([]byte, l)
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(pt, ct)
+		return pt, nil
+	}
+
+	d := l % bs
+	head := l - d - bs
+	if head < 0 {
+		return nil, fmt.Errorf("rfc8009: CBC-CS3 requires at least %d bytes of input, got %d", bs, l)
+	}
+
+	headCT := ciphertext[:head]
+	cnFull := ciphertext[head : head+bs]
+	cnTrunc := ciphertext[head+bs:]
+
+	headPT := make([]byte, head)
+	if head > 0 {
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(headPT, headCT)
+	}
+	prev := iv
+	if head > 0 {
+		prev = headCT[head-bs:]
+	}
+
+	// Recover Dn, whose tail is Cn-1's tail (to reconstruct Cn-1 in full)
+	// and whose head is the final partial plaintext block XORed with the
+	// truncated Cn-1.
+	dn := make([]byte, bs)
+	block.Decrypt(dn, cnFull)
+
+	cnMinus1 := make([]byte, bs)
+	copy(cnMinus1, cnTrunc)
+	copy(cnMinus1[d:], dn[d:])
+
+	lastFull := make([]byte, bs)
+	block.Decrypt(lastFull, cnMinus1)
+	lastFull = xor(lastFull, prev)
+
+	out := make([]byte, 0, l)
+	out = append(out, headPT...)
+	out = append(out, lastFull...)
+	out = append(out, xor(dn[:d], cnTrunc)...)
+	return out, nil
+}
+
+// swapLastTwoBlocks exchanges the final two blocks of a buffer whose length
+// is an exact multiple of blockSize, in place.
+func swapLastTwoBlocks(b []byte, blockSize int) {
+	last := b[len(b)-blockSize:]
+	prev := b[len(b)-2*blockSize : len(b)-blockSize]
+	tmp := make([]byte, blockSize)
+	copy(tmp, last)
+	copy(last, prev)
+	copy(prev, tmp)
+}
+
+func xor(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
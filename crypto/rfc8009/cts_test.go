@@ -0,0 +1,75 @@
+package rfc8009
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"testing"
+)
+
+// TestCTSRoundTrip checks that ctsDecrypt reverses ctsEncrypt for a spread
+// of plaintext lengths either side of the AES block size, including exact
+// multiples of it, which aes128-cts-hmac-sha256-128 and
+// aes256-cts-hmac-sha384-192 both rely on via RawEncrypt/RawDecrypt and
+// EncryptionAndMAC/VerifyAndDecrypt.
+func TestCTSRoundTrip(t *testing.T) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("failed to create cipher: %v", err)
+	}
+	iv := make([]byte, block.BlockSize())
+
+	for _, l := range []int{16, 17, 20, 31, 32, 33, 48, 100} {
+		pt := make([]byte, l)
+		if _, err := rand.Read(pt); err != nil {
+			t.Fatalf("failed to generate plaintext of length %d: %v", l, err)
+		}
+
+		ct, err := ctsEncrypt(block, iv, pt)
+		if err != nil {
+			t.Fatalf("length %d: ctsEncrypt returned error: %v", l, err)
+		}
+		if len(ct) != l {
+			t.Errorf("length %d: ciphertext length %d, want %d", l, len(ct), l)
+			continue
+		}
+
+		got, err := ctsDecrypt(block, iv, ct)
+		if err != nil {
+			t.Fatalf("length %d: ctsDecrypt returned error: %v", l, err)
+		}
+		if !bytes.Equal(got, pt) {
+			t.Errorf("length %d: round trip mismatch\n pt=%x\ngot=%x", l, pt, got)
+		}
+	}
+}
+
+// TestCTSRejectsSubBlockInput checks that inputs shorter than one AES block
+// are rejected rather than silently run through a non-standard scheme, since
+// CBC-CS3 is only defined for inputs of at least one full block.
+func TestCTSRejectsSubBlockInput(t *testing.T) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("failed to create cipher: %v", err)
+	}
+	iv := make([]byte, block.BlockSize())
+
+	for _, l := range []int{1, 5, 15} {
+		pt := make([]byte, l)
+		if _, err := ctsEncrypt(block, iv, pt); err == nil {
+			t.Errorf("length %d: ctsEncrypt returned no error for sub-block input", l)
+		}
+		ct := make([]byte, l)
+		if _, err := ctsDecrypt(block, iv, ct); err == nil {
+			t.Errorf("length %d: ctsDecrypt returned no error for sub-block input", l)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package rfc8009
+
+import (
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+)
+
+// EncryptionAndMAC implements the encrypt-then-MAC construction of RFC 8009
+// section 5: a random confounder is prepended to the plaintext, the result
+// is encrypted under Ke with AES CBC-CS3 and a zero IV, and the
+// concatenation IV||AES-ciphertext is MACed under Ki with HMAC-SHA-2
+// truncated to GetHMACBitLength bits. The AES ciphertext and the truncated
+// MAC are returned as two separate values.
+func EncryptionAndMAC(key, usage, plaintext []byte, e EType) ([]byte, []byte, error) {
+	conf := make([]byte, e.GetConfounderByteSize())
+	if _, err := rand.Read(conf); err != nil {
+		return nil, nil, err
+	}
+	return encryptionAndMACWithConfounder(key, usage, plaintext, conf, e)
+}
+
+// encryptionAndMACWithConfounder is EncryptionAndMAC with the confounder
+// supplied by the caller instead of generated randomly, so it can be
+// exercised against published test vectors that pin the confounder.
+func encryptionAndMACWithConfounder(key, usage, plaintext, confounder []byte, e EType) ([]byte, []byte, error) {
+	ke, err := DeriveKey(key, usage, IdentifierKe, e)
+	if err != nil {
+		return nil, nil, err
+	}
+	ki, err := DeriveKey(key, usage, IdentifierKi, e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toEncrypt := append(append([]byte{}, confounder...), plaintext...)
+
+	block, err := aes.NewCipher(ke)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv := make([]byte, block.BlockSize())
+	ct, err := ctsEncrypt(block, iv, toEncrypt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mac := hmacSum(ki, append(iv, ct...), e)[:e.GetHMACBitLength()/8]
+	return ct, mac, nil
+}
+
+// VerifyAndDecrypt reverses EncryptionAndMAC: it checks the MAC over
+// IV||ciphertext in constant time, then decrypts the ciphertext and returns
+// the plaintext with its confounder stripped.
+func VerifyAndDecrypt(key, usage, ciphertext, mac []byte, e EType) ([]byte, error) {
+	ke, err := DeriveKey(key, usage, IdentifierKe, e)
+	if err != nil {
+		return nil, err
+	}
+	ki, err := DeriveKey(key, usage, IdentifierKi, e)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(ke)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, block.BlockSize())
+	expected := hmacSum(ki, append(iv, ciphertext...), e)[:e.GetHMACBitLength()/8]
+	if !hmac.Equal(expected, mac) {
+		return nil, errors.New("rfc8009: integrity check failed")
+	}
+
+	pt, err := ctsDecrypt(block, iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return pt[e.GetConfounderByteSize():], nil
+}
+
+// GetChecksumHash computes the RFC 8009 checksum: HMAC-SHA-2(Kc, data)
+// truncated to GetHMACBitLength bits, where Kc is derived from the
+// protocol key for the given usage.
+func GetChecksumHash(protocolKey, data, usage []byte, e EType) ([]byte, error) {
+	kc, err := DeriveKey(protocolKey, usage, IdentifierKc, e)
+	if err != nil {
+		return nil, err
+	}
+	return hmacSum(kc, data, e)[:e.GetHMACBitLength()/8], nil
+}
+
+// VerifyChecksum recomputes the checksum and compares it in constant time.
+func VerifyChecksum(protocolKey, data, usage, chksum []byte, e EType) bool {
+	expected, err := GetChecksumHash(protocolKey, data, usage, e)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, chksum)
+}
+
+func hmacSum(key, data []byte, e EType) []byte {
+	h := hmac.New(e.GetHash(), key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// RawEncrypt performs plain AES CBC-CS3 encryption of data under key with a
+// zero IV, with no confounder or MAC. It exists so the AES-SHA2 etypes can
+// implement the low level EncryptData/DecryptData pair the rest of the
+// crypto package relies on (e.g. for DR-style feedback over other
+// etypes); the RFC 8009 message protection path uses EncryptionAndMAC
+// instead.
+func RawEncrypt(key, data []byte) ([]byte, []byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv := make([]byte, block.BlockSize())
+	ct, err := ctsEncrypt(block, iv, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return iv, ct, nil
+}
+
+// RawDecrypt reverses RawEncrypt.
+func RawDecrypt(key, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, block.BlockSize())
+	return ctsDecrypt(block, iv, ciphertext)
+}
@@ -0,0 +1,89 @@
+package rfc8009
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+)
+
+// Test vectors from RFC 8009 Appendix A (Kc/Ke/Ki derivation for
+// aes128-cts-hmac-sha256-128, key usage number 2).
+func TestDeriveKey_Aes128_Usage2(t *testing.T) {
+	baseKey, _ := hex.DecodeString("3705D96080C17728A0E800EAB6E0D23C")
+	usage := []byte{0x00, 0x00, 0x00, 0x02}
+
+	var e EType = testAes128{}
+
+	tests := []struct {
+		name       string
+		identifier byte
+		want       string
+	}{
+		{"Kc", IdentifierKc, "B31A018A48F54776F403E9A396325DC3"},
+		{"Ke", IdentifierKe, "9B197DD1E8C5609D6E67C3E37C62C72E"},
+		{"Ki", IdentifierKi, "9FDA0E56AB2D85E1569A688696C26A6C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeriveKey(baseKey, usage, tt.identifier, e)
+			if err != nil {
+				t.Fatalf("DeriveKey returned error: %v", err)
+			}
+			if got := hex.EncodeToString(got); !strings.EqualFold(got, tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEncryptionAndMACWithConfounder pins the confounder so EncryptionAndMAC
+// is deterministic and its output can be compared against a known-good
+// round trip. RFC 8009 Appendix A also publishes confidentiality/checksum
+// vectors (fixed confounder, plaintext, ciphertext and MAC) for this same
+// base key and usage, which encryptionAndMACWithConfounder exists to make
+// assertable; they are not reproduced here as this environment has no way
+// to verify a transcribed copy of those specific bytes against the RFC, so
+// this test only checks the injected-confounder path is self-consistent.
+func TestEncryptionAndMACWithConfounder(t *testing.T) {
+	baseKey, _ := hex.DecodeString("3705D96080C17728A0E800EAB6E0D23C")
+	usage := []byte{0x00, 0x00, 0x00, 0x02}
+	confounder, _ := hex.DecodeString("7E5895EAF2672435BAD817F545A37148")
+	plaintext := []byte("krb5 integrity and confidentiality")
+
+	var e EType = testAes128{}
+
+	ct1, mac1, err := encryptionAndMACWithConfounder(baseKey, usage, plaintext, confounder, e)
+	if err != nil {
+		t.Fatalf("encryptionAndMACWithConfounder returned error: %v", err)
+	}
+	ct2, mac2, err := encryptionAndMACWithConfounder(baseKey, usage, plaintext, confounder, e)
+	if err != nil {
+		t.Fatalf("encryptionAndMACWithConfounder returned error: %v", err)
+	}
+	if !bytes.Equal(ct1, ct2) || !bytes.Equal(mac1, mac2) {
+		t.Fatal("encryptionAndMACWithConfounder is not deterministic for a fixed confounder")
+	}
+
+	got, err := VerifyAndDecrypt(baseKey, usage, ct1, mac1, e)
+	if err != nil {
+		t.Fatalf("VerifyAndDecrypt returned error: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("round trip mismatch\n got=%q\nwant=%q", got, plaintext)
+	}
+}
+
+// testAes128 is a minimal rfc8009.EType stand-in used only to exercise the
+// KDF in this package's tests without depending on the full encryption
+// implementation in crypto/etype.
+type testAes128 struct{}
+
+func (testAes128) GetKeyByteSize() int        { return 16 }
+func (testAes128) GetKeySeedBitLength() int   { return 128 }
+func (testAes128) GetConfounderByteSize() int { return 16 }
+func (testAes128) GetHMACBitLength() int      { return 128 }
+func (testAes128) GetHash() func() hash.Hash  { return sha256.New }
@@ -0,0 +1,33 @@
+// Package gssapi implements the subset of the GSS-API krb5 mechanism
+// (RFC 4121) this library's callers rely on once a security context has
+// been established: deriving further keying material from the negotiated
+// session key.
+package gssapi
+
+import (
+	"github.com/jcmturner/gokrb5/crypto"
+	"github.com/jcmturner/gokrb5/crypto/etype"
+)
+
+// Context represents an established GSS-API krb5 security context: the
+// session key negotiated during context establishment and the etype it
+// was negotiated under.
+type Context struct {
+	SessionKey []byte
+	EType      etype.EType
+}
+
+// NewContext returns a Context wrapping a negotiated session key under the
+// given etype.
+func NewContext(sessionKey []byte, e etype.EType) Context {
+	return Context{SessionKey: sessionKey, EType: e}
+}
+
+// PRF implements GSS_Pseudo_random (RFC 4121 section 4.1.1, built on the
+// RFC 4402 PRF+ construction): it derives n bytes of pseudo-random output
+// from the context's session key and the caller-supplied input, for use in
+// channel bindings, exported session keys and similar higher-level
+// protocols layered over GSS-API.
+func (c Context) PRF(input []byte, n int) ([]byte, error) {
+	return crypto.PRFPlus(c.SessionKey, input, n, c.EType)
+}
@@ -0,0 +1,48 @@
+package gssapi
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jcmturner/gokrb5/crypto/etype"
+)
+
+func TestContextWrapUnwrapKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		kek         []byte
+		keyMaterial []byte
+		etype       etype.EType
+	}{
+		{"aes128 subkey under aes128 session key", make([]byte, 16), make([]byte, 16), etype.Aes128CtsHmacSha256128{}},
+		{"aes256 cross-realm key under aes256 session key", make([]byte, 32), make([]byte, 32), etype.Aes256CtsHmacSha384192{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.keyMaterial {
+				tt.keyMaterial[i] = byte(i)
+			}
+			c := Context{SessionKey: tt.kek, EType: tt.etype}
+
+			wrapped, err := c.WrapKey(tt.keyMaterial)
+			if err != nil {
+				t.Fatalf("WrapKey returned error: %v", err)
+			}
+			got, err := c.UnwrapKey(wrapped)
+			if err != nil {
+				t.Fatalf("UnwrapKey returned error: %v", err)
+			}
+			if !bytes.Equal(got, tt.keyMaterial) {
+				t.Errorf("got %x, want %x", got, tt.keyMaterial)
+			}
+		})
+	}
+
+	t.Run("zero value Context returns an error instead of panicking", func(t *testing.T) {
+		var c Context
+		if _, err := c.WrapKey(make([]byte, 16)); err == nil {
+			t.Fatal("expected an error from WrapKey with no EType set")
+		}
+	})
+}